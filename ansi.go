@@ -0,0 +1,41 @@
+// An ANSI-terminal renderer: clears the screen between generations and
+// draws live cells as filled blocks, so a run can be watched directly
+// in a terminal without piping through gnuplot.
+
+package main
+
+import "fmt"
+
+// AnsiRenderer renders each generation to stdout using ANSI escape
+// codes to clear the screen.
+type AnsiRenderer struct {
+	bounds int
+}
+
+func (r *AnsiRenderer) Init(bounds int) error {
+	r.bounds = bounds
+	return nil
+}
+
+func (r *AnsiRenderer) Frame(world World, gen int) error {
+	fmt.Print("\x1b[2J\x1b[H")
+	fmt.Printf("gen %d\n", gen)
+
+	half := r.bounds / 2
+	for y := -half; y < half; y++ {
+		for x := -half; x < half; x++ {
+			if world[Coord{x, y}].alive {
+				fmt.Print("█")
+			} else {
+				fmt.Print(" ")
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func (r *AnsiRenderer) Close() error {
+	return nil
+}
@@ -0,0 +1,184 @@
+// A renderer that collects every generation's frame and, on Close,
+// writes them out as a single animated PNG (APNG), so a whole run can
+// be shared as one file.
+//
+// The standard library can only encode plain, single-frame PNGs, so
+// each frame is first encoded that way and its IHDR/IDAT chunks are
+// reused to build the APNG's fcTL/fdAT chunk sequence, rather than
+// reimplementing a PNG/DEFLATE encoder from scratch.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"os"
+)
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// apngFrame holds one encoded frame: its pixel dimensions and the
+// compressed scanline data taken from a plain PNG encoding of it.
+type apngFrame struct {
+	width, height int
+	idat          []byte
+}
+
+// ApngRenderer rasterizes every generation onto a fixed-size canvas (so
+// all frames share one IHDR) and assembles them into path on Close.
+type ApngRenderer struct {
+	cellSize int
+	path     string
+	bounds   int
+	ihdr     []byte // real IHDR payload from the first frame, reused verbatim
+	frames   []apngFrame
+}
+
+func (r *ApngRenderer) Init(bounds int) error {
+	r.bounds = bounds
+	return nil
+}
+
+func (r *ApngRenderer) Frame(world World, gen int) error {
+	half := r.bounds / 2
+	img := rasterize(world, -half, -half, half-1, half-1, r.cellSize)
+
+	ihdr, idat, err := encodePNGChunks(img)
+	if err != nil {
+		return err
+	}
+	if r.ihdr == nil {
+		r.ihdr = ihdr
+	}
+
+	b := img.Bounds()
+	r.frames = append(r.frames, apngFrame{width: b.Dx(), height: b.Dy(), idat: idat})
+	return nil
+}
+
+func (r *ApngRenderer) Close() error {
+	if len(r.frames) == 0 {
+		return nil
+	}
+
+	if r.path == "" {
+		r.path = "cgol.apng"
+	}
+
+	file, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeAPNG(file, r.ihdr, r.frames)
+}
+
+// encodePNGChunks PNG-encodes img with the standard library and
+// extracts its IHDR payload and the concatenated payload of all its
+// IDAT chunks (a plain PNG may split one IDAT across several chunks;
+// concatenating their payloads back to back reproduces the single
+// zlib stream).
+func encodePNGChunks(img image.Image) (ihdr, idat []byte, err error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, nil, err
+	}
+
+	data := buf.Bytes()[len(pngSignature):]
+	for len(data) > 0 {
+		length := binary.BigEndian.Uint32(data[0:4])
+		typ := string(data[4:8])
+		payload := data[8 : 8+length]
+
+		switch typ {
+		case "IHDR":
+			ihdr = payload
+		case "IDAT":
+			idat = append(idat, payload...)
+		}
+
+		data = data[8+length+4:]
+	}
+
+	return ihdr, idat, nil
+}
+
+// writeAPNG writes frames to w as a single animated PNG, looping
+// forever at 8 frames per second. ihdr is the real IHDR payload taken
+// from a plain PNG encoding of the first frame, written verbatim so its
+// color type always matches what the IDAT/fdAT chunks actually hold.
+func writeAPNG(w io.Writer, ihdr []byte, frames []apngFrame) error {
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+
+	if err := writeChunk(w, "IHDR", ihdr); err != nil {
+		return err
+	}
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:4], uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:8], 0) // play forever
+	if err := writeChunk(w, "acTL", actl); err != nil {
+		return err
+	}
+
+	seq := uint32(0)
+	for i, f := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:4], seq)
+		seq++
+		binary.BigEndian.PutUint32(fctl[4:8], uint32(f.width))
+		binary.BigEndian.PutUint32(fctl[8:12], uint32(f.height))
+		binary.BigEndian.PutUint32(fctl[12:16], 0) // x_offset
+		binary.BigEndian.PutUint32(fctl[16:20], 0) // y_offset
+		binary.BigEndian.PutUint16(fctl[20:22], 1) // delay_num
+		binary.BigEndian.PutUint16(fctl[22:24], 8) // delay_den: 1/8s per frame
+		fctl[24] = 0                                // dispose_op: none
+		fctl[25] = 0                                // blend_op: source
+		if err := writeChunk(w, "fcTL", fctl); err != nil {
+			return err
+		}
+
+		if i == 0 {
+			if err := writeChunk(w, "IDAT", f.idat); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fdat := make([]byte, 4+len(f.idat))
+		binary.BigEndian.PutUint32(fdat[0:4], seq)
+		seq++
+		copy(fdat[4:], f.idat)
+		if err := writeChunk(w, "fdAT", fdat); err != nil {
+			return err
+		}
+	}
+
+	return writeChunk(w, "IEND", nil)
+}
+
+// writeChunk writes one length-prefixed, CRC-suffixed PNG chunk.
+func writeChunk(w io.Writer, typ string, data []byte) error {
+	var lengthBuf [4]byte
+	binary.BigEndian.PutUint32(lengthBuf[:], uint32(len(data)))
+	if _, err := w.Write(lengthBuf[:]); err != nil {
+		return err
+	}
+
+	body := append([]byte(typ), data...)
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+
+	var crcBuf [4]byte
+	binary.BigEndian.PutUint32(crcBuf[:], crc32.ChecksumIEEE(body))
+	_, err := w.Write(crcBuf[:])
+	return err
+}
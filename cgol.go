@@ -48,9 +48,11 @@ type Coord struct {
 // The world is a map of Coord and Cell
 type World map[Coord]Cell
 
-// Inflate inflates the world with dead cells surrounding
-// the live cells
-func (world World) Inflate(inflateChan, neighboursChan chan Coord) World {
+// Inflate inflates the world with dead cells surrounding the live
+// cells. Under the Torus topology neighbouring coordinates wrap around
+// the edges of the size x size world; under Bounded they are dropped
+// once they fall outside it.
+func (world World) Inflate(inflateChan, neighboursChan chan Coord, topology Topology, size int) World {
 	var newWorld World
 	newWorld = make(World)
 
@@ -58,7 +60,7 @@ func (world World) Inflate(inflateChan, neighboursChan chan Coord) World {
 	for coord, cell := range world {
 		newWorld[coord] = cell
 	}
-	
+
 	// Send the cells of the world to the inflate channel, so
 	// the neighbouring coordinates will be produced by the
 	// worker goroutines
@@ -67,12 +69,22 @@ func (world World) Inflate(inflateChan, neighboursChan chan Coord) World {
 			inflateChan <- coord
 		}
 	}()
-	
+
 	// Receive the neighbours in the neighbours channel
 	// We are expecting 8 neighbours for each cell,
 	// i.e. 9 coordinates for each cell in the map
 	for cnt := 0; cnt < 9 * len(world); cnt++ {
 		neighbour := <- neighboursChan
+
+		switch topology {
+		case Torus:
+			neighbour = Coord{wrap(neighbour.x, size), wrap(neighbour.y, size)}
+		case Bounded:
+			if !inBounds(neighbour.x, size) || !inBounds(neighbour.y, size) {
+				continue
+			}
+		}
+
 		if _, found := newWorld[neighbour]; !found {
 			newWorld[neighbour] = Cell{false, 0}
 		}
@@ -96,41 +108,50 @@ func (world World) Deflate() World {
 }
 
 // CountLiveNeighbours counts for each cell in the world its neighbouring
-// alive cells and updates its counter
-func (world World) CountLiveNeighbours() World {
+// alive cells and updates its counter. Under the Torus topology
+// neighbouring coordinates wrap around the edges of the size x size
+// world; under Bounded they are simply absent from world and so count
+// as dead, same as under Infinite.
+func (world World) CountLiveNeighbours(topology Topology, size int) World {
 	var newWorld World
 	newWorld = make(World)
-	
+
 	for coord, cell := range world {
 		n := 0
 		for i := -1; i < 2; i++ {
 			for j := -1; j < 2; j++ {
+				if i == 0 && j == 0 {
+					continue
+				}
 				c := Coord{coord.x + i, coord.y + j}
-				if (i != 0 || j != 0) && world[c].alive {
+				if topology == Torus {
+					c = Coord{wrap(c.x, size), wrap(c.y, size)}
+				}
+				if world[c].alive {
 					n = n+1
 				}
 			}
 		}
 		newWorld[coord] = Cell{cell.alive, n}
 	}
-	
+
 	return newWorld
 }
 
-// ApplyRules applies the rules to each cell of the world. This determines
+// ApplyRules applies rule to each cell of the world. This determines
 // the fate of the cell for the next tick.
-func (world World) ApplyRules() World {
+func (world World) ApplyRules(rule Rule) World {
 	var newWorld World
 	newWorld = make(World)
 
 	// apply the rules of the game to each cell
 	for coord, cell := range world {
 		if cell.alive {
-			if 1 < cell.n && cell.n < 4 {
+			if rule.survive[cell.n] {
 				newWorld[coord] = Cell{true, 0}
 			}
 		} else {
-			if cell.n == 3 {
+			if rule.birth[cell.n] {
 				newWorld[coord] = Cell{true, 0}
 			}
 		}
@@ -139,9 +160,10 @@ func (world World) ApplyRules() World {
 	return newWorld
 }
 
-// Tick computes the next generation of live cells in the world
-func (world World) Tick(inflateChan, neighboursChan chan Coord) World {
-	return world.Inflate(inflateChan, neighboursChan).CountLiveNeighbours().ApplyRules().Deflate()
+// Tick computes the next generation of live cells in the world, applying
+// rule to decide each cell's fate under topology.
+func (world World) Tick(inflateChan, neighboursChan chan Coord, rule Rule, topology Topology, size int) World {
+	return world.Inflate(inflateChan, neighboursChan, topology, size).CountLiveNeighbours(topology, size).ApplyRules(rule).Deflate()
 }
 
 // gnuplotHeader prints the header for gnuplot
@@ -164,8 +186,19 @@ func gnuplotWorld(world World) {
 
 func main() {
 	// Handle the command line arguments
-	ticks, size, pattern := handleCommandLine()
-	
+	ticks, size, pattern, saveRlePath, rule, engine, renderKind, cellSize, renderOut, topology, detectCycle := handleCommandLine()
+
+	var cycles *CycleDetector
+	if detectCycle > 0 {
+		cycles = NewCycleDetector(detectCycle)
+	}
+
+	renderer, err := NewRenderer(renderKind, cellSize, renderOut)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	start := time.Now()
 	
 	// Setup the communication channels for the goroutines
@@ -194,21 +227,78 @@ func main() {
 	for _, coord := range pattern {
 		world[coord] = Cell{true, 0}
 	}
-	
-	gnuplotHeader(size)
+	world = normalize(world, topology, size)
 
-	gnuplotWorld(world)
-	
-	for i := 0; i < ticks; i++ {
-		world = world.Tick(inflateChan, neighboursChan)
-		gnuplotWorld(world)
+	if err := renderer.Init(size); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	
+
+	if err := renderer.Frame(world, 0); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if engine == "hashlife" {
+		// Hashlife's whole point is skipping generations without
+		// computing them one by one, so unlike the naive engine it
+		// advances straight to the final generation in one jump and
+		// renders only the result; per-generation cycle detection needs
+		// the intermediate states this engine deliberately never builds.
+		universe := NewUniverse(world)
+		universe.Step(ticks, rule)
+		world = universe.ToWorld()
+		if err := renderer.Frame(world, ticks); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		for i := 0; i < ticks; i++ {
+			world = world.Tick(inflateChan, neighboursChan, rule, topology, size)
+			if err := renderer.Frame(world, i+1); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if reportCycle(cycles, world, i+1) {
+				break
+			}
+		}
+	}
+
+	if err := renderer.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if saveRlePath != "" {
+		if err := SaveRLE(saveRlePath, world, rule); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
 	elapsed := time.Since(start)
 	fmt.Printf("Elapsed: %s", elapsed)
 }
 
-func handleCommandLine() (ticks, size int, pattern []Coord) {
+// reportCycle feeds world at generation gen into cycles, if enabled,
+// and prints a classification line to stderr and returns true once it
+// detects a repeat, so main can stop the run early.
+func reportCycle(cycles *CycleDetector, world World, gen int) bool {
+	if cycles == nil {
+		return false
+	}
+
+	period, dx, dy, found := cycles.Check(world, gen)
+	if !found {
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "# period=%d offset=%d,%d at gen=%d\n", period, dx, dy, gen)
+	return true
+}
+
+func handleCommandLine() (ticks, size int, pattern []Coord, saveRlePath string, rule Rule, engine, renderKind string, cellSize int, renderOut string, topology Topology, detectCycle int) {
 	// Define our own usage message, overwriting the default one
 	flag.Usage = func() {
 		fmt.Fprint(os.Stderr, "Usage: cgol [flags] [pattern] | gnuplot --persist\n")
@@ -220,10 +310,46 @@ func handleCommandLine() (ticks, size int, pattern []Coord) {
 	flag.IntVar(&size, "size", 50, "size of the visible world in x and y direction")
 	var random *bool = flag.Bool("random", false, "generate a random pattern to start with")
 	var coordinatesOpt *string = flag.String("coordinates", "1,0;0,1;1,1;1,2;2,2", "semi-colon-separated list of coordinates")
+	var rlePath *string = flag.String("rle", "", "load the initial pattern from a RLE pattern file")
+	var saveRle *string = flag.String("save-rle", "", "save the live cells at the end of the run to a RLE pattern file")
+	var ruleOpt *string = flag.String("rule", "B3/S23", "birth/survival rulestring, e.g. B3/S23 (Life), B36/S23 (HighLife), B2/S (Seeds)")
+	flag.StringVar(&engine, "engine", "naive", "simulation engine to use: naive or hashlife (hashlife jumps straight to the final generation, so -render only ever gets the initial and final frames, however many -ticks there are)")
+	flag.StringVar(&renderKind, "render", "gnuplot", "renderer to use: gnuplot, ansi, png or apng")
+	flag.IntVar(&cellSize, "cell-size", 8, "pixel size of one cell, for the png and apng renderers")
+	flag.StringVar(&renderOut, "out", "", "output directory (png, default \".\") or file (apng, default \"cgol.apng\") for the rendered frames")
+	var topologyOpt *string = flag.String("topology", "infinite", "topology of the world: infinite, bounded or torus (bounded and torus require -engine naive)")
+	flag.IntVar(&detectCycle, "detect-cycle", 0, "hash the last N generations and stop once one repeats, reporting its period and offset (0 disables)")
 	flag.Parse()
-	
-	// Create a ranodm starting pattern or use the r-pentomino pattern
-	if *random {
+
+	saveRlePath = *saveRle
+
+	var err error
+	rule, err = ParseRule(*ruleOpt)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	topology, err = ParseTopology(*topologyOpt)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if engine == "hashlife" && topology != Infinite {
+		fmt.Println("cgol: -engine hashlife only supports -topology infinite")
+		os.Exit(1)
+	}
+
+	// Create a random starting pattern, load one from a RLE file, or
+	// use the r-pentomino pattern
+	if *rlePath != "" {
+		pattern, err = LoadRLE(*rlePath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	} else if *random {
 		// Generate a random pattern
 		//pattern = make([]Coord, size*size/5, size*size)
 		pattern = []Coord{}
@@ -253,6 +379,6 @@ func handleCommandLine() (ticks, size int, pattern []Coord) {
 			pattern[idx] = Coord{x, y}
 		}
 	}
-	
-	return ticks, size, pattern
+
+	return ticks, size, pattern, saveRlePath, rule, engine, renderKind, cellSize, renderOut, topology, detectCycle
 }
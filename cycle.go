@@ -0,0 +1,109 @@
+// Cycle detection classifies a run automatically into still lifes
+// (period 1, zero offset), oscillators (period p, zero offset) and
+// spaceships (period p, nonzero offset), without any gnuplot
+// post-processing.
+//
+// Each generation's live-cell set is hashed, after translating it so
+// its bounding box sits at the origin - this way a spaceship drifting
+// across the world still hashes the same as its earlier, shifted self.
+
+package main
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+)
+
+// CycleDetector remembers the last capacity generations' hashes and
+// reports when the current generation repeats one of them.
+type CycleDetector struct {
+	capacity int
+	buf      []cycleEntry
+	pos      int
+	full     bool
+	seen     map[uint64]cycleEntry
+}
+
+type cycleEntry struct {
+	hash       uint64
+	gen        int
+	minX, minY int
+}
+
+// NewCycleDetector returns a detector that remembers the last capacity
+// generations.
+func NewCycleDetector(capacity int) *CycleDetector {
+	return &CycleDetector{
+		capacity: capacity,
+		buf:      make([]cycleEntry, capacity),
+		seen:     make(map[uint64]cycleEntry),
+	}
+}
+
+// Check hashes world's live cells and records it as generation gen. If
+// the hash matches one still within the detector's window, it returns
+// the period (the generation distance to that earlier occurrence) and
+// the offset between their bounding boxes, with found set to true.
+func (d *CycleDetector) Check(world World, gen int) (period, dx, dy int, found bool) {
+	hash, minX, minY := hashWorld(world)
+
+	if prev, ok := d.seen[hash]; ok {
+		period = gen - prev.gen
+		dx = minX - prev.minX
+		dy = minY - prev.minY
+		found = true
+	}
+
+	if d.full {
+		evicted := d.buf[d.pos]
+		if cur, ok := d.seen[evicted.hash]; ok && cur.gen == evicted.gen {
+			delete(d.seen, evicted.hash)
+		}
+	}
+
+	entry := cycleEntry{hash: hash, gen: gen, minX: minX, minY: minY}
+	d.buf[d.pos] = entry
+	d.seen[hash] = entry
+	d.pos = (d.pos + 1) % d.capacity
+	if d.pos == 0 {
+		d.full = true
+	}
+
+	return
+}
+
+// hashWorld returns a FNV-64 hash of world's live cells, translated so
+// their bounding box minimum sits at the origin, along with that
+// minimum (used to compute the offset between two matching
+// generations).
+func hashWorld(world World) (hash uint64, minX, minY int) {
+	if len(world) == 0 {
+		return 0, 0, 0
+	}
+
+	minX, minY, _, _ = boundingBox(world)
+
+	coords := make([]Coord, 0, len(world))
+	for coord, cell := range world {
+		if cell.alive {
+			coords = append(coords, Coord{coord.x - minX, coord.y - minY})
+		}
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].x != coords[j].x {
+			return coords[i].x < coords[j].x
+		}
+		return coords[i].y < coords[j].y
+	})
+
+	h := fnv.New64()
+	var buf [8]byte
+	for _, c := range coords {
+		binary.BigEndian.PutUint32(buf[0:4], uint32(c.x))
+		binary.BigEndian.PutUint32(buf[4:8], uint32(c.y))
+		h.Write(buf[:])
+	}
+
+	return h.Sum64(), minX, minY
+}
@@ -0,0 +1,381 @@
+// An alternative simulation engine based on Bill Gosper's Hashlife
+// algorithm. Unlike the naive map-based Tick, which touches every live
+// cell and its neighbours on every generation, Hashlife represents the
+// universe as a quadtree of canonicalized Node values: structurally
+// identical subtrees are interned so they share a single pointer, and
+// the future of each interned node is memoized. This lets huge, sparse
+// or highly repetitive patterns (guns, breeders, ...) be simulated far
+// beyond what the naive engine can reach.
+//
+// Select this engine with -engine hashlife.
+
+package main
+
+// Node is a node of the Hashlife quadtree. A node at level k covers a
+// 2^k x 2^k square. Level-0 nodes are single cells (alive holds their
+// state); all other levels are split into four level-(k-1) children.
+type Node struct {
+	level      int
+	alive      bool // only meaningful at level 0
+	nw, ne, sw, se *Node
+	population int64
+
+	// results caches the center 2^(k-1) x 2^(k-1) square of this node,
+	// 2^(k-2) generations in the future, keyed by the rule it was
+	// computed under (the result depends on the rule, but the node
+	// itself, interned by structure alone, does not). Computed lazily
+	// by Result.
+	results map[Rule]*Node
+}
+
+var deadLeaf = &Node{level: 0, alive: false}
+var aliveLeaf = &Node{level: 0, alive: true, population: 1}
+
+// nodeCache interns every non-leaf node ever constructed, keyed by its
+// four children, so structurally identical subtrees share one pointer.
+var nodeCache = make(map[[4]*Node]*Node)
+
+// emptyCache memoizes the canonical, entirely dead node of each level.
+var emptyCache = make(map[int]*Node)
+
+// leafFor returns the canonical leaf node for the given cell state.
+func leafFor(alive bool) *Node {
+	if alive {
+		return aliveLeaf
+	}
+	return deadLeaf
+}
+
+// NewNode returns the canonical node with the given children, interning
+// it so that equal children always yield the same pointer.
+func NewNode(nw, ne, sw, se *Node) *Node {
+	key := [4]*Node{nw, ne, sw, se}
+	if n, ok := nodeCache[key]; ok {
+		return n
+	}
+
+	n := &Node{
+		level:      nw.level + 1,
+		nw:         nw,
+		ne:         ne,
+		sw:         sw,
+		se:         se,
+		population: nw.population + ne.population + sw.population + se.population,
+	}
+	nodeCache[key] = n
+	return n
+}
+
+// EmptyNode returns the canonical, entirely dead node at the given level.
+func EmptyNode(level int) *Node {
+	if level == 0 {
+		return deadLeaf
+	}
+	if n, ok := emptyCache[level]; ok {
+		return n
+	}
+	e := EmptyNode(level - 1)
+	n := NewNode(e, e, e, e)
+	emptyCache[level] = n
+	return n
+}
+
+// getCell reads the state of the cell at (x, y) within n, where (0, 0)
+// is n's top-left corner.
+func getCell(n *Node, x, y int) bool {
+	if n.level == 0 {
+		return n.alive
+	}
+	half := 1 << uint(n.level-1)
+	switch {
+	case x < half && y < half:
+		return getCell(n.nw, x, y)
+	case x >= half && y < half:
+		return getCell(n.ne, x-half, y)
+	case x < half && y >= half:
+		return getCell(n.sw, x, y-half)
+	default:
+		return getCell(n.se, x-half, y-half)
+	}
+}
+
+// centeredHorizontal combines the eastern half of w and the western half
+// of e, both level k-1, into a new node one level down that straddles
+// their shared border.
+func centeredHorizontal(w, e *Node) *Node {
+	return NewNode(w.ne, e.nw, w.se, e.sw)
+}
+
+// centeredVertical combines the southern half of top and the northern
+// half of bottom, both level k-1, into a new node one level down that
+// straddles their shared border.
+func centeredVertical(top, bottom *Node) *Node {
+	return NewNode(top.sw, top.se, bottom.nw, bottom.ne)
+}
+
+// centeredSubnode returns the node one level down from n (level k-1)
+// made of n's four innermost grandchildren, i.e. the exact center of n.
+func centeredSubnode(n *Node) *Node {
+	return NewNode(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// Result returns the center 2^(k-1) x 2^(k-1) square of n, 2^(k-2)
+// generations in the future, computing and caching it if necessary.
+// n must be at least level 2.
+func (n *Node) Result(rule Rule) *Node {
+	if res, ok := n.results[rule]; ok {
+		return res
+	}
+
+	var res *Node
+	if n.level == 2 {
+		res = life4x4to2x2(n, rule)
+	} else {
+		n00, n02, n20, n22 := n.nw, n.ne, n.sw, n.se
+		n01 := centeredHorizontal(n.nw, n.ne)
+		n21 := centeredHorizontal(n.sw, n.se)
+		n10 := centeredVertical(n.nw, n.sw)
+		n12 := centeredVertical(n.ne, n.se)
+		n11 := centeredSubnode(n)
+
+		r00, r01, r02 := n00.Result(rule), n01.Result(rule), n02.Result(rule)
+		r10, r11, r12 := n10.Result(rule), n11.Result(rule), n12.Result(rule)
+		r20, r21, r22 := n20.Result(rule), n21.Result(rule), n22.Result(rule)
+
+		t00 := NewNode(r00, r01, r10, r11)
+		t01 := NewNode(r01, r02, r11, r12)
+		t10 := NewNode(r10, r11, r20, r21)
+		t11 := NewNode(r11, r12, r21, r22)
+
+		res = NewNode(t00.Result(rule), t01.Result(rule), t10.Result(rule), t11.Result(rule))
+	}
+
+	if n.results == nil {
+		n.results = make(map[Rule]*Node)
+	}
+	n.results[rule] = res
+	return res
+}
+
+// life4x4to2x2 is the base case of Result: given a level-2 node (a 4x4
+// square of individual cells), it computes the center 2x2 square one
+// generation later by direct neighbour counting.
+func life4x4to2x2(n *Node, rule Rule) *Node {
+	var grid [4][4]bool
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			grid[x][y] = getCell(n, x, y)
+		}
+	}
+
+	next := func(x, y int) bool {
+		count := 0
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if dx == 0 && dy == 0 {
+					continue
+				}
+				nx, ny := x+dx, y+dy
+				if nx >= 0 && nx < 4 && ny >= 0 && ny < 4 && grid[nx][ny] {
+					count++
+				}
+			}
+		}
+		if grid[x][y] {
+			return rule.survive[count]
+		}
+		return rule.birth[count]
+	}
+
+	return NewNode(leafFor(next(1, 1)), leafFor(next(2, 1)), leafFor(next(1, 2)), leafFor(next(2, 2)))
+}
+
+// Universe wraps a Hashlife root node together with the world
+// coordinates of its top-left corner, since nodes themselves carry no
+// position (identical patterns at different positions share one node).
+type Universe struct {
+	root *Node
+	x, y int
+}
+
+// NewUniverse builds a Universe from the live cells of world.
+func NewUniverse(world World) *Universe {
+	if len(world) == 0 {
+		return &Universe{root: EmptyNode(2)}
+	}
+
+	minX, minY, maxX, maxY := boundingBox(world)
+	width, height := maxX-minX+1, maxY-minY+1
+
+	level, size := 2, 4
+	for size < width || size < height {
+		size *= 2
+		level++
+	}
+
+	getAlive := func(x, y int) bool {
+		return world[Coord{x, y}].alive
+	}
+
+	return &Universe{root: buildNode(level, minX, minY, getAlive), x: minX, y: minY}
+}
+
+// buildNode recursively builds a level node covering world coordinates
+// [x0, x0+2^level) x [y0, y0+2^level), reading cell state from getAlive.
+func buildNode(level, x0, y0 int, getAlive func(x, y int) bool) *Node {
+	if level == 0 {
+		return leafFor(getAlive(x0, y0))
+	}
+	half := 1 << uint(level-1)
+	return NewNode(
+		buildNode(level-1, x0, y0, getAlive),
+		buildNode(level-1, x0+half, y0, getAlive),
+		buildNode(level-1, x0, y0+half, getAlive),
+		buildNode(level-1, x0+half, y0+half, getAlive),
+	)
+}
+
+// ToWorld converts u back into the map-based World representation, e.g.
+// so it can be rendered with the existing gnuplot/RLE code.
+func (u *Universe) ToWorld() World {
+	world := make(World)
+	collectAlive(u.root, u.x, u.y, 1<<uint(u.root.level), world)
+	return world
+}
+
+func collectAlive(n *Node, x0, y0, size int, world World) {
+	if n.population == 0 {
+		return
+	}
+	if n.level == 0 {
+		if n.alive {
+			world[Coord{x0, y0}] = Cell{true, 0}
+		}
+		return
+	}
+	half := size / 2
+	collectAlive(n.nw, x0, y0, half, world)
+	collectAlive(n.ne, x0+half, y0, half, world)
+	collectAlive(n.sw, x0, y0+half, half, world)
+	collectAlive(n.se, x0+half, y0+half, half, world)
+}
+
+// expand grows u's root by one level, keeping its content exactly
+// centered and surrounded by empty space.
+func (u *Universe) expand() {
+	n := u.root
+	e := EmptyNode(n.level - 1)
+
+	u.root = NewNode(
+		NewNode(e, e, e, n.nw),
+		NewNode(e, e, n.ne, e),
+		NewNode(e, n.sw, e, e),
+		NewNode(n.se, e, e, e),
+	)
+
+	half := 1 << uint(n.level-1)
+	u.x -= half
+	u.y -= half
+}
+
+// isPadded reports whether u.root has at least one level of guaranteed
+// empty border around its content, which Result needs to stay correct:
+// every grandchild of the root except the four innermost ones (which
+// make up centeredSubnode, the true center) must be empty.
+func (u *Universe) isPadded() bool {
+	if u.root.level < 3 {
+		return false
+	}
+
+	n := u.root
+	e := EmptyNode(n.level - 2)
+	outerRing := [12]*Node{
+		n.nw.nw, n.nw.ne, n.nw.sw,
+		n.ne.nw, n.ne.ne, n.ne.se,
+		n.sw.nw, n.sw.sw, n.sw.se,
+		n.se.ne, n.se.sw, n.se.se,
+	}
+	for _, c := range outerRing {
+		if c != e {
+			return false
+		}
+	}
+	return true
+}
+
+// Step advances u by exactly n generations under rule. Hashlife jumps of
+// 2^(level-2) generations are taken whenever they fit within n; any
+// remainder smaller than the smallest available jump falls back to
+// naive single-generation steps.
+func (u *Universe) Step(n int, rule Rule) {
+	for n > 0 {
+		for !u.isPadded() {
+			u.expand()
+		}
+		// isPadded only guarantees the population fits within the center
+		// square that Result returns, with no slack: over the course of
+		// the jump, population can drift by up to the jump distance and
+		// spill past that square's edge, silently losing the cells that
+		// spilled. One more level of headroom gives exactly enough
+		// margin for the jump to stay complete.
+		u.expand()
+
+		jump := 1 << uint(u.root.level-2)
+		if jump > n {
+			break
+		}
+
+		half := jump
+		u.root = u.root.Result(rule)
+		u.x += half
+		u.y += half
+		n -= jump
+	}
+
+	if n > 0 {
+		world := u.ToWorld()
+		for i := 0; i < n; i++ {
+			world = tickOnce(world, rule)
+		}
+		rebuilt := NewUniverse(world)
+		u.root, u.x, u.y = rebuilt.root, rebuilt.x, rebuilt.y
+	}
+}
+
+// tickOnce computes one generation of world under rule with a plain,
+// single-threaded neighbour count. Used by Universe.Step to finish off
+// a remainder too small for a Hashlife jump.
+func tickOnce(world World, rule Rule) World {
+	live := make(map[Coord]bool, len(world))
+	for coord, cell := range world {
+		if cell.alive {
+			live[coord] = true
+		}
+	}
+
+	counts := make(map[Coord]int)
+	for coord := range live {
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if dx == 0 && dy == 0 {
+					continue
+				}
+				counts[Coord{coord.x + dx, coord.y + dy}]++
+			}
+		}
+	}
+
+	newWorld := make(World)
+	for coord := range live {
+		if rule.survive[counts[coord]] {
+			newWorld[coord] = Cell{true, 0}
+		}
+	}
+	for coord, n := range counts {
+		if !live[coord] && rule.birth[n] {
+			newWorld[coord] = Cell{true, 0}
+		}
+	}
+
+	return newWorld
+}
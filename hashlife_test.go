@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+// liveCellsOf is liveCells from topology_test.go, reused here for World
+// values produced by the hashlife engine.
+
+// TestUniverseStepMatchesNaive checks that Hashlife jumps (Universe.Step)
+// agree with the naive tickOnce reference for a glider, both advanced in
+// one big jump and in several smaller chunked calls on the same Universe.
+// This pins the bug where an insufficiently padded root let population
+// drift past the square Result returns, silently losing cells.
+func TestUniverseStepMatchesNaive(t *testing.T) {
+	pattern := []Coord{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}}
+	start := make(World)
+	for _, c := range pattern {
+		start[c] = Cell{true, 0}
+	}
+
+	want := start
+	for i := 0; i < 8; i++ {
+		want = tickOnce(want, DefaultRule)
+	}
+
+	oneJump := NewUniverse(start)
+	oneJump.Step(8, DefaultRule)
+	if !sameLiveCells(liveCells(oneJump.ToWorld()), liveCells(want)) {
+		t.Fatalf("Step(8): got %v, want %v", liveCells(oneJump.ToWorld()), liveCells(want))
+	}
+
+	chunked := NewUniverse(start)
+	for i := 0; i < 8; i++ {
+		chunked.Step(1, DefaultRule)
+	}
+	if !sameLiveCells(liveCells(chunked.ToWorld()), liveCells(want)) {
+		t.Fatalf("8x Step(1): got %v, want %v", liveCells(chunked.ToWorld()), liveCells(want))
+	}
+}
+
+// TestResultCacheKeyedByRule checks that a node's memoized Result does
+// not leak between rules: running the hashlife engine once under
+// DefaultRule must not poison a later run of the same pattern under a
+// different rule, since Result's cache used to be keyed by node
+// structure alone.
+func TestResultCacheKeyedByRule(t *testing.T) {
+	rPentomino := []Coord{{1, 0}, {2, 0}, {0, 1}, {1, 1}, {1, 2}}
+	start := make(World)
+	for _, c := range rPentomino {
+		start[c] = Cell{true, 0}
+	}
+
+	highLife, err := ParseRule("B36/S23")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := start
+	for i := 0; i < 9; i++ {
+		want = tickOnce(want, highLife)
+	}
+
+	// Warm the shared node/result caches with a DefaultRule run first.
+	warm := NewUniverse(start)
+	warm.Step(9, DefaultRule)
+
+	u := NewUniverse(start)
+	u.Step(9, highLife)
+	if !sameLiveCells(liveCells(u.ToWorld()), liveCells(want)) {
+		t.Fatalf("after a DefaultRule run in the same process, B36/S23 got %v, want %v", liveCells(u.ToWorld()), liveCells(want))
+	}
+}
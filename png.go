@@ -0,0 +1,43 @@
+// A renderer that writes one numbered PNG frame per generation, each
+// cropped to the bounding box of the live cells.
+
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// PngRenderer writes each generation as frame_<gen>.png into dir, with
+// cellSize pixels per cell.
+type PngRenderer struct {
+	cellSize int
+	dir      string
+}
+
+func (r *PngRenderer) Init(bounds int) error {
+	if r.dir == "" {
+		r.dir = "."
+	}
+	return os.MkdirAll(r.dir, 0755)
+}
+
+func (r *PngRenderer) Frame(world World, gen int) error {
+	minX, minY, maxX, maxY := boundingBox(world)
+	img := rasterize(world, minX, minY, maxX, maxY, r.cellSize)
+
+	path := filepath.Join(r.dir, fmt.Sprintf("frame_%06d.png", gen))
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return png.Encode(file, img)
+}
+
+func (r *PngRenderer) Close() error {
+	return nil
+}
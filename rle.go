@@ -0,0 +1,263 @@
+// Support for the Run Length Encoded (RLE) pattern format, which is the
+// de-facto standard used by the Life community (LifeWiki, Golly, ...) for
+// exchanging patterns such as gliders, guns and spaceships.
+//
+// A RLE file consists of optional comment lines starting with '#', a
+// single header line of the form
+//
+//	x = <width>, y = <height>[, rule = <rulestring>]
+//
+// and a run-length encoded body made up of tokens <count><tag> where tag
+// is one of:
+//
+//	b   dead cell(s)
+//	o   alive cell(s)
+//	$   end of row
+//	!   end of pattern
+//
+// A missing count means one. The '#P' and '#R' comment lines carry an
+// optional (x, y) offset for the top-left corner of the pattern.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadRLE reads the RLE-encoded pattern file at path and returns the
+// coordinates of its live cells, shifted by the offset found in a
+// leading "#P x y" or "#R x y" comment line, if any.
+func LoadRLE(path string) ([]Coord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	offsetX, offsetY := 0, 0
+	var body strings.Builder
+	headerSeen := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, "#P") || strings.HasPrefix(line, "#R") {
+				fields := strings.Fields(line[2:])
+				if len(fields) >= 2 {
+					offsetX, err = strconv.Atoi(fields[0])
+					if err != nil {
+						return nil, fmt.Errorf("rle: bad offset in %q: %v", line, err)
+					}
+					offsetY, err = strconv.Atoi(fields[1])
+					if err != nil {
+						return nil, fmt.Errorf("rle: bad offset in %q: %v", line, err)
+					}
+				}
+			}
+			continue
+		}
+
+		if !headerSeen && strings.Contains(line, "x") && strings.Contains(line, "=") {
+			// The header line, e.g. "x = 3, y = 3, rule = B3/S23".
+			// We do not need width/height to place the pattern, since
+			// coordinates are absolute, but we still validate the line.
+			headerSeen = true
+			continue
+		}
+
+		body.WriteString(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !headerSeen {
+		return nil, fmt.Errorf("rle: missing header line")
+	}
+
+	return parseRLEBody(body.String(), offsetX, offsetY)
+}
+
+// parseRLEBody decodes the run-length encoded body of a RLE file into
+// live cell coordinates, offset by (offsetX, offsetY).
+func parseRLEBody(body string, offsetX, offsetY int) ([]Coord, error) {
+	var pattern []Coord
+	x, y := 0, 0
+	count := 0
+
+	for _, r := range body {
+		switch {
+		case r >= '0' && r <= '9':
+			count = count*10 + int(r-'0')
+		case r == 'b':
+			x += atLeastOne(count)
+			count = 0
+		case r == 'o':
+			n := atLeastOne(count)
+			for i := 0; i < n; i++ {
+				pattern = append(pattern, Coord{x + offsetX, y + offsetY})
+				x++
+			}
+			count = 0
+		case r == '$':
+			y += atLeastOne(count)
+			x = 0
+			count = 0
+		case r == '!':
+			return pattern, nil
+		default:
+			return nil, fmt.Errorf("rle: unexpected character %q in body", r)
+		}
+	}
+
+	return nil, fmt.Errorf("rle: body is missing the terminating '!'")
+}
+
+// atLeastOne returns count, or 1 if count is zero (an absent RLE count
+// means a single repetition).
+func atLeastOne(count int) int {
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+// SaveRLE writes the live cells of world to path in RLE format, wrapping
+// the encoded body at 70 columns as is customary for the format, and
+// recording rule in the header.
+func SaveRLE(path string, world World, rule Rule) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if len(world) == 0 {
+		_, err := fmt.Fprintf(file, "x = 0, y = 0, rule = %s\n", rule)
+		return err
+	}
+
+	minX, minY, maxX, maxY := boundingBox(world)
+	width, height := maxX-minX+1, maxY-minY+1
+
+	if _, err := fmt.Fprintf(file, "x = %d, y = %d, rule = %s\n", width, height, rule); err != nil {
+		return err
+	}
+
+	body := encodeRLEBody(world, minX, minY, maxX, maxY)
+	return writeWrapped(file, body, 70)
+}
+
+// boundingBox returns the smallest rectangle, in inclusive coordinates,
+// that contains every live cell in world.
+func boundingBox(world World) (minX, minY, maxX, maxY int) {
+	first := true
+	for coord, cell := range world {
+		if !cell.alive {
+			continue
+		}
+		if first {
+			minX, maxX = coord.x, coord.x
+			minY, maxY = coord.y, coord.y
+			first = false
+			continue
+		}
+		if coord.x < minX {
+			minX = coord.x
+		}
+		if coord.x > maxX {
+			maxX = coord.x
+		}
+		if coord.y < minY {
+			minY = coord.y
+		}
+		if coord.y > maxY {
+			maxY = coord.y
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// encodeRLEBody run-length encodes the live cells of world within the
+// given bounds into the b/o/$/! token stream, without line wrapping.
+func encodeRLEBody(world World, minX, minY, maxX, maxY int) string {
+	var out strings.Builder
+
+	for y := minY; y <= maxY; y++ {
+		runChar := byte(0)
+		runLen := 0
+
+		flush := func() {
+			if runLen == 0 {
+				return
+			}
+			if runLen > 1 {
+				out.WriteString(strconv.Itoa(runLen))
+			}
+			out.WriteByte(runChar)
+			runLen = 0
+		}
+
+		for x := minX; x <= maxX; x++ {
+			alive := world[Coord{x, y}].alive
+			c := byte('b')
+			if alive {
+				c = 'o'
+			}
+			if c == runChar {
+				runLen++
+			} else {
+				flush()
+				runChar = c
+				runLen = 1
+			}
+		}
+		// Trailing dead cells at the end of a row need not be encoded.
+		if runChar == 'o' {
+			flush()
+		}
+		if y < maxY {
+			out.WriteByte('$')
+		}
+	}
+	out.WriteByte('!')
+
+	return out.String()
+}
+
+// writeWrapped writes s, a RLE body made up of <count><tag> tokens, to
+// w, breaking lines so that no line exceeds width columns without ever
+// splitting a token (which would corrupt the count for stricter RLE
+// readers than LoadRLE).
+func writeWrapped(w *os.File, s string, width int) error {
+	var line strings.Builder
+
+	for start := 0; start < len(s); {
+		end := start
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		end++ // include the tag byte following the digits
+		token := s[start:end]
+		start = end
+
+		if line.Len() > 0 && line.Len()+len(token) > width {
+			if _, err := fmt.Fprintln(w, line.String()); err != nil {
+				return err
+			}
+			line.Reset()
+		}
+		line.WriteString(token)
+	}
+
+	_, err := fmt.Fprintln(w, line.String())
+	return err
+}
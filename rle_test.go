@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveRLEWrapsOnTokenBoundaries checks that a saved pattern wide
+// enough to need multi-digit run counts near the 70-column wrap point
+// never has a line break fall inside a <count><tag> token, which would
+// corrupt the count for any RLE reader stricter than LoadRLE.
+func TestSaveRLEWrapsOnTokenBoundaries(t *testing.T) {
+	// Alive cells spaced two apart encode as a run of distinct "o2b"
+	// tokens rather than one long run, so the body is long enough to
+	// need wrapping and actually forces a break between tokens instead
+	// of collapsing to a single "<n>o!" token that never needs to wrap.
+	world := make(World)
+	for x := 0; x < 200; x += 3 {
+		world[Coord{x, 0}] = Cell{true, 0}
+	}
+
+	path := filepath.Join(t.TempDir(), "wide.rle")
+	if err := SaveRLE(path, world, DefaultRule); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header line
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if last := line[len(line)-1]; last >= '0' && last <= '9' {
+			t.Fatalf("line splits a token, ending mid-count: %q", line)
+		}
+		if len(line) > 70 {
+			t.Fatalf("line exceeds 70 columns: %q", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
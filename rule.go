@@ -0,0 +1,94 @@
+// Parsing of Golly-style B/S rulestrings, which describe an
+// outer-totalistic two-state cellular automaton: a dead cell with n
+// live neighbours is born if n is one of the birth counts, and a live
+// cell with n live neighbours survives if n is one of the survival
+// counts. Conway's Game of Life is B3/S23; other well known rules such
+// as HighLife (B36/S23), Seeds (B2/S) or Replicator (B1357/S1357) use
+// the same notation.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule holds the birth and survival neighbour counts of an
+// outer-totalistic rule, indexed by neighbour count 0-8.
+type Rule struct {
+	birth   [9]bool
+	survive [9]bool
+}
+
+// DefaultRule is Conway's Game of Life, B3/S23.
+var DefaultRule = Rule{
+	birth:   [9]bool{3: true},
+	survive: [9]bool{2: true, 3: true},
+}
+
+// ParseRule parses a rulestring of the form "B<digits>/S<digits>"
+// (case-insensitive) into a Rule. Digits must be in 0-8 and no other
+// letters are accepted.
+func ParseRule(s string) (Rule, error) {
+	var rule Rule
+
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return rule, fmt.Errorf("rule: expected \"B.../S...\", got %q", s)
+	}
+
+	for _, part := range parts {
+		if len(part) == 0 {
+			return rule, fmt.Errorf("rule: empty clause in %q", s)
+		}
+
+		switch part[0] {
+		case 'B', 'b':
+			counts, err := parseCounts(part[1:])
+			if err != nil {
+				return rule, fmt.Errorf("rule: %v", err)
+			}
+			rule.birth = counts
+		case 'S', 's':
+			counts, err := parseCounts(part[1:])
+			if err != nil {
+				return rule, fmt.Errorf("rule: %v", err)
+			}
+			rule.survive = counts
+		default:
+			return rule, fmt.Errorf("rule: unknown clause %q in %q", part, s)
+		}
+	}
+
+	return rule, nil
+}
+
+// String formats rule back into its "B<digits>/S<digits>" rulestring.
+func (rule Rule) String() string {
+	var b, s strings.Builder
+	for n := 0; n <= 8; n++ {
+		if rule.birth[n] {
+			b.WriteString(strconv.Itoa(n))
+		}
+		if rule.survive[n] {
+			s.WriteString(strconv.Itoa(n))
+		}
+	}
+	return fmt.Sprintf("B%s/S%s", b.String(), s.String())
+}
+
+// parseCounts turns a string of digits into a [9]bool lookup table with
+// the corresponding indices set to true.
+func parseCounts(digits string) ([9]bool, error) {
+	var counts [9]bool
+
+	for _, r := range digits {
+		if r < '0' || r > '8' {
+			return counts, fmt.Errorf("neighbour count out of range 0-8: %q", r)
+		}
+		counts[r-'0'] = true
+	}
+
+	return counts, nil
+}
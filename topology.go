@@ -0,0 +1,73 @@
+// Topologies describe the shape of the world: an unbounded plane, a
+// bounded rectangle beyond which cells are permanently dead, or a torus
+// where the edges wrap around.
+
+package main
+
+import "fmt"
+
+// Topology selects how coordinates outside the visible world of the
+// given size are treated.
+type Topology int
+
+const (
+	// Infinite keeps today's behaviour: the world grows without limit.
+	Infinite Topology = iota
+	// Bounded treats every cell outside [-size/2, size/2) as
+	// permanently dead.
+	Bounded
+	// Torus wraps coordinates outside [-size/2, size/2) around to the
+	// opposite edge.
+	Torus
+)
+
+// ParseTopology parses the -topology flag value.
+func ParseTopology(s string) (Topology, error) {
+	switch s {
+	case "", "infinite":
+		return Infinite, nil
+	case "bounded":
+		return Bounded, nil
+	case "torus":
+		return Torus, nil
+	default:
+		return Infinite, fmt.Errorf("topology: unknown topology %q", s)
+	}
+}
+
+// wrap folds v into [-size/2, size/2), as Torus does at the edges.
+func wrap(v, size int) int {
+	half := size / 2
+	return ((v+half)%size+size)%size - half
+}
+
+// inBounds reports whether v lies within [-size/2, size-size/2), the
+// same size-wide range that wrap folds coordinates into, as Bounded
+// requires.
+func inBounds(v, size int) bool {
+	half := size / 2
+	return v >= -half && v < size-half
+}
+
+// normalize applies topology to the coordinates already present in
+// world: Torus wraps them around, Bounded drops the ones that fall
+// outside, and Infinite leaves world untouched.
+func normalize(world World, topology Topology, size int) World {
+	if topology == Infinite {
+		return world
+	}
+
+	newWorld := make(World)
+	for coord, cell := range world {
+		switch topology {
+		case Torus:
+			newWorld[Coord{wrap(coord.x, size), wrap(coord.y, size)}] = cell
+		case Bounded:
+			if inBounds(coord.x, size) && inBounds(coord.y, size) {
+				newWorld[coord] = cell
+			}
+		}
+	}
+
+	return newWorld
+}
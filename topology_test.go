@@ -0,0 +1,108 @@
+package main
+
+import "testing"
+
+// newTickWorkers sets up the inflate/neighbours channel pair and worker
+// goroutines that World.Tick needs, mirroring what main does.
+func newTickWorkers() (chan Coord, chan Coord) {
+	inflateChan := make(chan Coord, cntWorkers)
+	neighboursChan := make(chan Coord)
+
+	for i := 0; i < cntWorkers; i++ {
+		go func() {
+			for coord := range inflateChan {
+				for i := -1; i < 2; i++ {
+					for j := -1; j < 2; j++ {
+						neighboursChan <- Coord{coord.x + i, coord.y + j}
+					}
+				}
+			}
+		}()
+	}
+
+	return inflateChan, neighboursChan
+}
+
+// liveCells returns the set of alive coordinates in world.
+func liveCells(world World) map[Coord]bool {
+	live := make(map[Coord]bool)
+	for coord, cell := range world {
+		if cell.alive {
+			live[coord] = true
+		}
+	}
+	return live
+}
+
+func sameLiveCells(a, b map[Coord]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for coord := range a {
+		if !b[coord] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestBlinkerBounded checks that a vertical blinker on a 3x3 Bounded
+// grid oscillates between its vertical and horizontal phase forever,
+// exactly as it would on an infinite grid.
+func TestBlinkerBounded(t *testing.T) {
+	const size = 3
+	inflateChan, neighboursChan := newTickWorkers()
+
+	vertical := World{
+		{0, -1}: Cell{true, 0},
+		{0, 0}:  Cell{true, 0},
+		{0, 1}:  Cell{true, 0},
+	}
+	horizontal := World{
+		{-1, 0}: Cell{true, 0},
+		{0, 0}:  Cell{true, 0},
+		{1, 0}:  Cell{true, 0},
+	}
+
+	world := normalize(vertical, Bounded, size)
+	for gen := 1; gen <= 4; gen++ {
+		world = world.Tick(inflateChan, neighboursChan, DefaultRule, Bounded, size)
+
+		want := horizontal
+		if gen%2 == 0 {
+			want = vertical
+		}
+		if !sameLiveCells(liveCells(world), liveCells(want)) {
+			t.Fatalf("gen %d: got %v, want %v", gen, liveCells(world), liveCells(want))
+		}
+	}
+}
+
+// TestGliderTorus checks that a glider on a Torus returns to its
+// starting configuration after 4*gcd(w,h) ticks.
+func TestGliderTorus(t *testing.T) {
+	const size = 8 // a square torus, so gcd(w,h) == w == h
+	inflateChan, neighboursChan := newTickWorkers()
+
+	pattern := []Coord{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}}
+	start := make(World)
+	for _, coord := range pattern {
+		start[coord] = Cell{true, 0}
+	}
+	start = normalize(start, Torus, size)
+	startLive := liveCells(start)
+
+	world := start
+	period := 4 * size
+	for gen := 1; gen <= period; gen++ {
+		world = world.Tick(inflateChan, neighboursChan, DefaultRule, Torus, size)
+
+		if gen < period && sameLiveCells(liveCells(world), startLive) {
+			t.Fatalf("glider returned to start early, at gen %d", gen)
+		}
+	}
+
+	if !sameLiveCells(liveCells(world), startLive) {
+		t.Fatalf("gen %d: got %v, want %v", period, liveCells(world), startLive)
+	}
+}